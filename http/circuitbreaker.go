@@ -0,0 +1,134 @@
+package http
+
+import (
+	"context"
+	"errors"
+	nethttp "net/http"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen 熔断器处于打开状态时返回，请求未真正发出
+var ErrCircuitOpen = errors.New("xhttp: circuit open")
+
+// CircuitBreakerConfig 熔断器配置，按host维度独立统计
+type CircuitBreakerConfig struct {
+	// WindowSize 滑动窗口保留的最近请求数
+	WindowSize int
+	// MinRequests 窗口内达到该请求数后才会评估是否熔断
+	MinRequests int
+	// FailureThreshold 窗口内失败占比达到该阈值时打开熔断，取值范围(0,1]
+	FailureThreshold float64
+	// Cooldown 熔断打开后多久允许放行一次探测请求
+	Cooldown time.Duration
+}
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+// breakerState 单个host的熔断状态
+type breakerState struct {
+	mu       sync.Mutex
+	outcomes []bool
+	state    circuitState
+	openedAt time.Time
+}
+
+// allow 判断当前是否允许放行请求；熔断打开且冷却时间已过时，放行一个探测请求并转入半开状态
+func (b *breakerState) allow(cfg *CircuitBreakerConfig) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if time.Since(b.openedAt) < cfg.Cooldown {
+			return false
+		}
+		b.state = circuitHalfOpen
+		return true
+	case circuitHalfOpen:
+		// 已有一个探测请求在途，其余请求继续快速失败
+		return false
+	default:
+		return true
+	}
+}
+
+// record 记录一次请求结果，半开探测成功则关闭熔断，失败则重新打开；
+// 闭合状态下按滑动窗口统计失败率，达到阈值即打开熔断
+func (b *breakerState) record(cfg *CircuitBreakerConfig, success bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == circuitHalfOpen {
+		if success {
+			b.state = circuitClosed
+			b.outcomes = nil
+		} else {
+			b.state = circuitOpen
+			b.openedAt = time.Now()
+		}
+		return
+	}
+
+	b.outcomes = append(b.outcomes, success)
+	if len(b.outcomes) > cfg.WindowSize {
+		b.outcomes = b.outcomes[len(b.outcomes)-cfg.WindowSize:]
+	}
+	if len(b.outcomes) < cfg.MinRequests {
+		return
+	}
+
+	failures := 0
+	for _, ok := range b.outcomes {
+		if !ok {
+			failures++
+		}
+	}
+	if float64(failures)/float64(len(b.outcomes)) >= cfg.FailureThreshold {
+		b.state = circuitOpen
+		b.openedAt = time.Now()
+	}
+}
+
+// breakerFor 获取（或创建）host对应的熔断状态，状态跟随entity持续存在
+func (r *entity) breakerFor(host string) *breakerState {
+	r.breakersMu.Lock()
+	defer r.breakersMu.Unlock()
+
+	if r.breakers == nil {
+		r.breakers = map[string]*breakerState{}
+	}
+	b, ok := r.breakers[host]
+	if !ok {
+		b = &breakerState{}
+		r.breakers[host] = b
+	}
+	return b
+}
+
+// circuitBreakerMiddleware 熔断打开时直接返回ErrCircuitOpen，不再真正发起请求
+func (r *entity) circuitBreakerMiddleware() Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, p *parameter) (*nethttp.Response, error) {
+			cfg := p.circuitBreaker
+			if cfg == nil {
+				return next(ctx, p)
+			}
+
+			b := r.breakerFor(hostOf(p.url))
+			if !b.allow(cfg) {
+				return nil, ErrCircuitOpen
+			}
+
+			resp, err := next(ctx, p)
+			b.record(cfg, err == nil && resp != nil && resp.StatusCode < 500)
+			return resp, err
+		}
+	}
+}
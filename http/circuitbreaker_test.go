@@ -0,0 +1,111 @@
+package http
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerStateClosedToOpen(t *testing.T) {
+	cfg := &CircuitBreakerConfig{WindowSize: 4, MinRequests: 4, FailureThreshold: 0.5}
+	b := &breakerState{}
+
+	if !b.allow(cfg) {
+		t.Fatal("allow() = false, want true while closed")
+	}
+
+	b.record(cfg, true)
+	b.record(cfg, true)
+	b.record(cfg, false)
+	if b.state != circuitClosed {
+		t.Fatalf("state = %v, want circuitClosed below MinRequests/FailureThreshold", b.state)
+	}
+
+	b.record(cfg, false)
+	if b.state != circuitOpen {
+		t.Fatalf("state = %v, want circuitOpen once failure rate reaches threshold", b.state)
+	}
+}
+
+func TestBreakerStateOpenRejectsUntilCooldown(t *testing.T) {
+	cfg := &CircuitBreakerConfig{WindowSize: 4, MinRequests: 1, FailureThreshold: 0.5, Cooldown: time.Hour}
+	b := &breakerState{}
+
+	b.record(cfg, false)
+	if b.state != circuitOpen {
+		t.Fatalf("state = %v, want circuitOpen", b.state)
+	}
+
+	if b.allow(cfg) {
+		t.Fatal("allow() = true, want false while Cooldown has not elapsed")
+	}
+	if b.state != circuitOpen {
+		t.Fatalf("state = %v, want circuitOpen until Cooldown has elapsed", b.state)
+	}
+
+	cfg.Cooldown = 0
+	if !b.allow(cfg) {
+		t.Fatal("allow() = false, want true once Cooldown has elapsed (transition to half-open)")
+	}
+	if b.state != circuitHalfOpen {
+		t.Fatalf("state = %v, want circuitHalfOpen after probe is let through", b.state)
+	}
+}
+
+func TestBreakerStateHalfOpenRejectsConcurrentProbes(t *testing.T) {
+	cfg := &CircuitBreakerConfig{WindowSize: 4, MinRequests: 1, FailureThreshold: 0.5, Cooldown: 0}
+	b := &breakerState{}
+
+	b.record(cfg, false)
+	b.allow(cfg) // transitions to half-open, consuming the single probe slot
+
+	if b.allow(cfg) {
+		t.Error("allow() = true, want false for a second request while a probe is already in flight")
+	}
+}
+
+func TestBreakerStateHalfOpenSuccessCloses(t *testing.T) {
+	cfg := &CircuitBreakerConfig{WindowSize: 4, MinRequests: 1, FailureThreshold: 0.5, Cooldown: 0}
+	b := &breakerState{}
+
+	b.record(cfg, false)
+	b.allow(cfg)
+	b.record(cfg, true)
+
+	if b.state != circuitClosed {
+		t.Fatalf("state = %v, want circuitClosed after a successful half-open probe", b.state)
+	}
+	if len(b.outcomes) != 0 {
+		t.Errorf("outcomes = %v, want reset after closing", b.outcomes)
+	}
+}
+
+func TestBreakerStateHalfOpenFailureReopens(t *testing.T) {
+	cfg := &CircuitBreakerConfig{WindowSize: 4, MinRequests: 1, FailureThreshold: 0.5, Cooldown: 0}
+	b := &breakerState{}
+
+	b.record(cfg, false)
+	b.allow(cfg)
+	b.record(cfg, false)
+
+	if b.state != circuitOpen {
+		t.Fatalf("state = %v, want circuitOpen after a failed half-open probe", b.state)
+	}
+}
+
+func TestBreakerStateWindowCapsOutcomes(t *testing.T) {
+	// FailureThreshold is set unreachably high so the breaker never opens mid-test,
+	// isolating the window-capping behavior from the open/close state machine.
+	cfg := &CircuitBreakerConfig{WindowSize: 2, MinRequests: 2, FailureThreshold: 2}
+	b := &breakerState{}
+
+	b.record(cfg, true)
+	b.record(cfg, true)
+	b.record(cfg, false)
+
+	if len(b.outcomes) != cfg.WindowSize {
+		t.Fatalf("len(outcomes) = %d, want capped at WindowSize %d", len(b.outcomes), cfg.WindowSize)
+	}
+	if b.outcomes[0] != true || b.outcomes[1] != false {
+		t.Errorf("outcomes = %v, want the oldest entry evicted", b.outcomes)
+	}
+}
@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"net"
+	"net/http"
+	neturl "net/url"
+	"testing"
+)
+
+func newTestEntity() *entity {
+	return New().(*entity)
+}
+
+func TestClientForDefaultReusesPooledClient(t *testing.T) {
+	e := newTestEntity()
+	p := e.newParameter()
+
+	if got := e.clientFor(p); got != e.client {
+		t.Errorf("clientFor() = %p, want the pooled entity client %p", got, e.client)
+	}
+}
+
+func TestClientForWithClientOverride(t *testing.T) {
+	e := newTestEntity()
+	p := e.newParameter()
+	override := &http.Client{}
+	p.clientOverride = override
+
+	if got := e.clientFor(p); got != override {
+		t.Errorf("clientFor() = %p, want the overridden client %p", got, override)
+	}
+}
+
+func TestClientForWithTransport(t *testing.T) {
+	e := newTestEntity()
+	p := e.newParameter()
+	rt := &http.Transport{}
+	p.transport = rt
+
+	got := e.clientFor(p)
+	if got == e.client {
+		t.Fatal("clientFor() returned the pooled client, want a dedicated one built from WithTransport")
+	}
+	if got.Transport != rt {
+		t.Errorf("clientFor().Transport = %v, want %v", got.Transport, rt)
+	}
+}
+
+func TestClientForWithProxyAndDialContext(t *testing.T) {
+	e := newTestEntity()
+	p := e.newParameter()
+	p.proxy = func(*http.Request) (*neturl.URL, error) { return nil, nil }
+	p.dialContext = func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, nil
+	}
+
+	got := e.clientFor(p)
+	if got == e.client {
+		t.Fatal("clientFor() returned the pooled client, want a dedicated one built from WithProxy/WithDialContext")
+	}
+	tr, ok := got.Transport.(*http.Transport)
+	if !ok {
+		t.Fatalf("clientFor().Transport = %T, want *http.Transport", got.Transport)
+	}
+	if tr.Proxy == nil {
+		t.Error("Transport.Proxy not set from WithProxy")
+	}
+	if tr.DialContext == nil {
+		t.Error("Transport.DialContext not set from WithDialContext")
+	}
+}
+
+func TestClientForWithCustomTLSConfig(t *testing.T) {
+	e := newTestEntity()
+	p := e.newParameter()
+	p.tLSClientConfig = nil // distinct from e.defaultTLSClientConfig
+
+	got := e.clientFor(p)
+	if got == e.client {
+		t.Fatal("clientFor() returned the pooled client, want a dedicated one built from a custom TLS config")
+	}
+}
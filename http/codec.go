@@ -0,0 +1,197 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"mime/multipart"
+	neturl "net/url"
+	"strings"
+	"sync"
+
+	"github.com/thoohv5/xhttp/util/transform"
+)
+
+// 内置支持的媒体类型
+const (
+	ContentTypeJSON      = "application/json"
+	ContentTypeForm      = "application/x-www-form-urlencoded"
+	ContentTypeXML       = "application/xml"
+	ContentTypeMultipart = "multipart/form-data"
+)
+
+// Codec 请求/响应内容编解码器
+type Codec interface {
+	// Marshal 将v编码为请求体，并返回对应的Content-Type
+	Marshal(v interface{}) ([]byte, string, error)
+	// Unmarshal 将data按自身格式解码到v
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// codecRegistry 按媒体类型注册的编解码器集合
+type codecRegistry struct {
+	mu     sync.RWMutex
+	codecs map[string]Codec
+}
+
+func newCodecRegistry() *codecRegistry {
+	r := &codecRegistry{codecs: map[string]Codec{}}
+	r.register(ContentTypeJSON, jsonCodec{})
+	r.register(ContentTypeForm, formCodec{})
+	r.register(ContentTypeXML, xmlCodec{})
+	r.register(ContentTypeMultipart, multipartCodec{})
+	return r
+}
+
+func (r *codecRegistry) register(contentType string, codec Codec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.codecs[contentType] = codec
+}
+
+func (r *codecRegistry) get(contentType string) (Codec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	c, ok := r.codecs[mediaType(contentType)]
+	return c, ok
+}
+
+var defaultRegistry = newCodecRegistry()
+
+// RegisterCodec 注册/覆盖一个媒体类型对应的编解码器，可借此接入sonic等自定义编解码器
+func RegisterCodec(contentType string, codec Codec) {
+	defaultRegistry.register(contentType, codec)
+}
+
+// mediaType 截取Content-Type中的媒体类型部分，忽略charset等参数
+func mediaType(contentType string) string {
+	if i := strings.IndexByte(contentType, ';'); i >= 0 {
+		contentType = contentType[:i]
+	}
+	return strings.TrimSpace(contentType)
+}
+
+// jsonCodec 默认JSON编解码器
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, string, error) {
+	data, err := json.Marshal(v)
+	return data, ContentTypeJSON, err
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// formCodec 默认x-www-form-urlencoded编解码器，仅支持map[string]interface{}
+type formCodec struct{}
+
+func (formCodec) Marshal(v interface{}) ([]byte, string, error) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("form codec marshal err, v must be map[string]interface{}, got %T", v)
+	}
+	values := neturl.Values{}
+	for key, val := range m {
+		values.Add(key, transform.Strval(val))
+	}
+	return []byte(values.Encode()), ContentTypeForm, nil
+}
+
+func (formCodec) Unmarshal(data []byte, v interface{}) error {
+	values, err := neturl.ParseQuery(string(data))
+	if err != nil {
+		return fmt.Errorf("form codec unmarshal parse err, %w", err)
+	}
+	m, ok := v.(*map[string]interface{})
+	if !ok {
+		return fmt.Errorf("form codec unmarshal err, v must be *map[string]interface{}, got %T", v)
+	}
+	if *m == nil {
+		*m = map[string]interface{}{}
+	}
+	for key := range values {
+		(*m)[key] = values.Get(key)
+	}
+	return nil
+}
+
+// xmlCodec 默认XML编解码器
+type xmlCodec struct{}
+
+func (xmlCodec) Marshal(v interface{}) ([]byte, string, error) {
+	data, err := xml.Marshal(v)
+	return data, ContentTypeXML, err
+}
+
+func (xmlCodec) Unmarshal(data []byte, v interface{}) error {
+	return xml.Unmarshal(data, v)
+}
+
+// file 一个multipart文件字段
+type file struct {
+	field    string
+	filename string
+	reader   io.Reader
+}
+
+// multipartForm multipart/form-data请求体的中间表示
+type multipartForm struct {
+	fields map[string]interface{}
+	files  []file
+}
+
+// multipartCodec 默认multipart/form-data编解码器，仅支持编码
+type multipartCodec struct{}
+
+func (multipartCodec) Marshal(v interface{}) ([]byte, string, error) {
+	form, ok := v.(*multipartForm)
+	if !ok {
+		return nil, "", fmt.Errorf("multipart codec marshal err, v must be *multipartForm, got %T", v)
+	}
+
+	buf := &bytes.Buffer{}
+	w := multipart.NewWriter(buf)
+	for key, val := range form.fields {
+		if err := w.WriteField(key, transform.Strval(val)); err != nil {
+			return nil, "", fmt.Errorf("multipart codec write field err, %w", err)
+		}
+	}
+	for _, f := range form.files {
+		fw, err := w.CreateFormFile(f.field, f.filename)
+		if err != nil {
+			return nil, "", fmt.Errorf("multipart codec create file err, %w", err)
+		}
+		if _, err = io.Copy(fw, f.reader); err != nil {
+			return nil, "", fmt.Errorf("multipart codec copy file err, %w", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", fmt.Errorf("multipart codec close err, %w", err)
+	}
+
+	return buf.Bytes(), w.FormDataContentType(), nil
+}
+
+func (multipartCodec) Unmarshal(_ []byte, _ interface{}) error {
+	return fmt.Errorf("multipart codec does not support unmarshal")
+}
+
+// marshalBody 按请求codec（未指定时:文件优先走multipart，否则JSON）编码请求体
+func (p *parameter) marshalBody() ([]byte, string, error) {
+	if len(p.files) > 0 {
+		codec := p.requestCodec
+		if codec == nil {
+			codec = multipartCodec{}
+		}
+		return codec.Marshal(&multipartForm{fields: p.param, files: p.files})
+	}
+
+	codec := p.requestCodec
+	if codec == nil {
+		codec = jsonCodec{}
+	}
+	return codec.Marshal(p.param)
+}
@@ -0,0 +1,222 @@
+package http
+
+import (
+	"bytes"
+	"mime"
+	"mime/multipart"
+	"strings"
+	"testing"
+)
+
+func TestJSONCodecRoundTrip(t *testing.T) {
+	type payload struct {
+		Name string `json:"name"`
+	}
+	codec := jsonCodec{}
+
+	data, contentType, err := codec.Marshal(payload{Name: "a"})
+	if err != nil {
+		t.Fatalf("Marshal() err = %v", err)
+	}
+	if contentType != ContentTypeJSON {
+		t.Errorf("contentType = %q, want %q", contentType, ContentTypeJSON)
+	}
+
+	var got payload
+	if err := codec.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() err = %v", err)
+	}
+	if got.Name != "a" {
+		t.Errorf("got = %+v, want Name=a", got)
+	}
+}
+
+func TestFormCodecMarshalUnmarshal(t *testing.T) {
+	codec := formCodec{}
+
+	data, contentType, err := codec.Marshal(map[string]interface{}{"a": 1, "b": "x"})
+	if err != nil {
+		t.Fatalf("Marshal() err = %v", err)
+	}
+	if contentType != ContentTypeForm {
+		t.Errorf("contentType = %q, want %q", contentType, ContentTypeForm)
+	}
+
+	var out map[string]interface{}
+	if err := codec.Unmarshal(data, &out); err != nil {
+		t.Fatalf("Unmarshal() err = %v", err)
+	}
+	if out["a"] != "1" || out["b"] != "x" {
+		t.Errorf("out = %v, want a=1 b=x", out)
+	}
+}
+
+func TestFormCodecMarshalWrongType(t *testing.T) {
+	codec := formCodec{}
+	if _, _, err := codec.Marshal("not a map"); err == nil {
+		t.Error("Marshal() err = nil, want error for non-map input")
+	}
+}
+
+func TestFormCodecUnmarshalWrongType(t *testing.T) {
+	codec := formCodec{}
+	var out interface{}
+	if err := codec.Unmarshal([]byte("a=1"), &out); err == nil {
+		t.Error("Unmarshal() err = nil, want error when v is not *map[string]interface{}")
+	}
+}
+
+func TestXMLCodecRoundTrip(t *testing.T) {
+	type payload struct {
+		Name string `xml:"name"`
+	}
+	codec := xmlCodec{}
+
+	data, contentType, err := codec.Marshal(payload{Name: "a"})
+	if err != nil {
+		t.Fatalf("Marshal() err = %v", err)
+	}
+	if contentType != ContentTypeXML {
+		t.Errorf("contentType = %q, want %q", contentType, ContentTypeXML)
+	}
+
+	var got payload
+	if err := codec.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Unmarshal() err = %v", err)
+	}
+	if got.Name != "a" {
+		t.Errorf("got = %+v, want Name=a", got)
+	}
+}
+
+func TestMultipartCodecMarshal(t *testing.T) {
+	form := &multipartForm{
+		fields: map[string]interface{}{"a": "1"},
+		files:  []file{{field: "f", filename: "a.txt", reader: strings.NewReader("hi")}},
+	}
+	codec := multipartCodec{}
+
+	data, contentType, err := codec.Marshal(form)
+	if err != nil {
+		t.Fatalf("Marshal() err = %v", err)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("ParseMediaType() err = %v", err)
+	}
+	if mediaType != ContentTypeMultipart {
+		t.Errorf("mediaType = %q, want %q", mediaType, ContentTypeMultipart)
+	}
+
+	mr := multipart.NewReader(bytes.NewReader(data), params["boundary"])
+	form2, err := mr.ReadForm(1 << 20)
+	if err != nil {
+		t.Fatalf("ReadForm() err = %v", err)
+	}
+	if got := form2.Value["a"]; len(got) != 1 || got[0] != "1" {
+		t.Errorf("field a = %v, want [1]", got)
+	}
+	if len(form2.File["f"]) != 1 || form2.File["f"][0].Filename != "a.txt" {
+		t.Errorf("file f = %v, want a.txt", form2.File["f"])
+	}
+}
+
+func TestMultipartCodecMarshalWrongType(t *testing.T) {
+	codec := multipartCodec{}
+	if _, _, err := codec.Marshal("not a form"); err == nil {
+		t.Error("Marshal() err = nil, want error for non-*multipartForm input")
+	}
+}
+
+func TestMultipartCodecUnmarshalUnsupported(t *testing.T) {
+	codec := multipartCodec{}
+	if err := codec.Unmarshal(nil, nil); err == nil {
+		t.Error("Unmarshal() err = nil, want error since multipartCodec only supports encoding")
+	}
+}
+
+func TestCodecRegistryGetByMediaType(t *testing.T) {
+	reg := newCodecRegistry()
+
+	codec, ok := reg.get("application/json; charset=utf-8")
+	if !ok {
+		t.Fatal("get() ok = false, want true for a registered media type with params")
+	}
+	if _, ok := codec.(jsonCodec); !ok {
+		t.Errorf("codec = %T, want jsonCodec", codec)
+	}
+
+	if _, ok := reg.get("text/plain"); ok {
+		t.Error("get() ok = true, want false for an unregistered media type")
+	}
+}
+
+func TestCodecRegistryRegisterOverrides(t *testing.T) {
+	reg := newCodecRegistry()
+	custom := jsonCodec{}
+	reg.register(ContentTypeXML, custom)
+
+	codec, ok := reg.get(ContentTypeXML)
+	if !ok || codec != Codec(custom) {
+		t.Errorf("get() = (%v, %v), want the overridden codec", codec, ok)
+	}
+}
+
+func TestMediaType(t *testing.T) {
+	cases := map[string]string{
+		"application/json; charset=utf-8": "application/json",
+		"application/json":                "application/json",
+		" application/json ":              "application/json",
+	}
+	for in, want := range cases {
+		if got := mediaType(in); got != want {
+			t.Errorf("mediaType(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestMarshalBodyPrefersMultipartWhenFilesPresent(t *testing.T) {
+	p := &parameter{
+		param: map[string]interface{}{"a": "1"},
+		files: []file{{field: "f", filename: "a.txt", reader: strings.NewReader("hi")}},
+	}
+
+	_, contentType, err := p.marshalBody()
+	if err != nil {
+		t.Fatalf("marshalBody() err = %v", err)
+	}
+	if !strings.HasPrefix(contentType, ContentTypeMultipart) {
+		t.Errorf("contentType = %q, want multipart when files are present", contentType)
+	}
+}
+
+func TestMarshalBodyDefaultsToJSON(t *testing.T) {
+	p := &parameter{param: map[string]interface{}{"a": "1"}}
+
+	data, contentType, err := p.marshalBody()
+	if err != nil {
+		t.Fatalf("marshalBody() err = %v", err)
+	}
+	if contentType != ContentTypeJSON {
+		t.Errorf("contentType = %q, want %q", contentType, ContentTypeJSON)
+	}
+	if !strings.Contains(string(data), `"a":"1"`) {
+		t.Errorf("data = %s, want JSON containing a:1", data)
+	}
+}
+
+func TestMarshalBodyRespectsRequestCodec(t *testing.T) {
+	p := &parameter{param: map[string]interface{}{"a": "1"}, requestCodec: formCodec{}}
+
+	data, contentType, err := p.marshalBody()
+	if err != nil {
+		t.Fatalf("marshalBody() err = %v", err)
+	}
+	if contentType != ContentTypeForm {
+		t.Errorf("contentType = %q, want %q", contentType, ContentTypeForm)
+	}
+	if string(data) != "a=1" {
+		t.Errorf("data = %s, want a=1", data)
+	}
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"net/http/httputil"
+	"strings"
+)
+
+// maxDumpBodySize 单条请求/响应dump（含请求行、header与body）最多记录的字节数，超出部分截断
+const maxDumpBodySize = 4 << 10
+
+// newRequestID 生成一个uuid v4格式的请求ID，用于跨日志/dump关联同一次调用
+func newRequestID() string {
+	var b [16]byte
+	if _, err := rand.Read(b[:]); err != nil {
+		return ""
+	}
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// isMultipart 判断是否为multipart/form-data，dump时跳过其body，避免记录巨大的二进制内容
+func isMultipart(contentType string) bool {
+	return strings.HasPrefix(contentType, "multipart/form-data")
+}
+
+// dumpRequest 在请求发出前记录完整的请求报文
+func (r *entity) dumpRequest(p *parameter, req *nethttp.Request) {
+	if !p.debug {
+		return
+	}
+	data, err := httputil.DumpRequestOut(req, !isMultipart(req.Header.Get("Content-Type")))
+	if err != nil {
+		r.writeDump(p, fmt.Sprintf("[%s] dump request err: %s", p.requestID, err.Error()))
+		return
+	}
+	r.writeDump(p, fmt.Sprintf("[%s] request:\n%s", p.requestID, capDump(data)))
+}
+
+// dumpResponse 在收到响应后记录完整的响应报文
+func (r *entity) dumpResponse(p *parameter, resp *nethttp.Response) {
+	if !p.debug {
+		return
+	}
+	data, err := httputil.DumpResponse(resp, !isMultipart(resp.Header.Get("Content-Type")))
+	if err != nil {
+		r.writeDump(p, fmt.Sprintf("[%s] dump response err: %s", p.requestID, err.Error()))
+		return
+	}
+	r.writeDump(p, fmt.Sprintf("[%s] response:\n%s", p.requestID, capDump(data)))
+}
+
+// capDump 超出上限的内容截断，避免dump巨大的body
+func capDump(data []byte) []byte {
+	if len(data) <= maxDumpBodySize {
+		return data
+	}
+	out := make([]byte, 0, maxDumpBodySize+len("...(truncated)"))
+	out = append(out, data[:maxDumpBodySize]...)
+	out = append(out, []byte("...(truncated)")...)
+	return out
+}
+
+// writeDump 优先通过ILogger输出，未设置时写入dumpWriter
+func (r *entity) writeDump(p *parameter, line string) {
+	if p.log != nil {
+		p.log.Println(line)
+		return
+	}
+	if p.dumpWriter != nil {
+		_, _ = io.WriteString(p.dumpWriter, line+"\n")
+	}
+}
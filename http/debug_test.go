@@ -0,0 +1,144 @@
+package http
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeLogger struct {
+	lines []string
+}
+
+func (l *fakeLogger) Println(v ...interface{}) {
+	l.lines = append(l.lines, fmt.Sprint(v...))
+}
+
+func TestNewRequestIDFormat(t *testing.T) {
+	id := newRequestID()
+	parts := strings.Split(id, "-")
+	if len(parts) != 5 {
+		t.Fatalf("newRequestID() = %q, want 5 dash-separated groups", id)
+	}
+	wantLens := []int{8, 4, 4, 4, 12}
+	for i, part := range parts {
+		if len(part) != wantLens[i] {
+			t.Errorf("group %d = %q, want length %d", i, part, wantLens[i])
+		}
+	}
+	if parts[2][0] != '4' {
+		t.Errorf("version nibble = %q, want '4' (uuid v4)", parts[2][0:1])
+	}
+}
+
+func TestNewRequestIDUnique(t *testing.T) {
+	if newRequestID() == newRequestID() {
+		t.Error("newRequestID() returned the same value twice")
+	}
+}
+
+func TestIsMultipart(t *testing.T) {
+	cases := map[string]bool{
+		"multipart/form-data; boundary=xyz": true,
+		"application/json":                  false,
+		"":                                  false,
+	}
+	for contentType, want := range cases {
+		if got := isMultipart(contentType); got != want {
+			t.Errorf("isMultipart(%q) = %v, want %v", contentType, got, want)
+		}
+	}
+}
+
+func TestCapDump(t *testing.T) {
+	small := []byte("hello")
+	if got := capDump(small); !bytes.Equal(got, small) {
+		t.Errorf("capDump() on small input = %q, want unchanged", got)
+	}
+
+	big := bytes.Repeat([]byte("a"), maxDumpBodySize+100)
+	got := capDump(big)
+	if len(got) != maxDumpBodySize+len("...(truncated)") {
+		t.Fatalf("len(capDump()) = %d, want %d", len(got), maxDumpBodySize+len("...(truncated)"))
+	}
+	if !bytes.HasSuffix(got, []byte("...(truncated)")) {
+		t.Error("capDump() did not end with the truncation marker")
+	}
+}
+
+func TestWriteDumpPrefersLoggerOverWriter(t *testing.T) {
+	e := newTestEntity()
+	log := &fakeLogger{}
+	buf := &bytes.Buffer{}
+	p := e.newParameter()
+	p.log = log
+	p.dumpWriter = buf
+
+	e.writeDump(p, "line one")
+
+	if len(log.lines) != 1 || log.lines[0] != "line one" {
+		t.Errorf("logger lines = %v, want [\"line one\"]", log.lines)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("dumpWriter = %q, want untouched when an ILogger is set", buf.String())
+	}
+}
+
+func TestWriteDumpFallsBackToWriter(t *testing.T) {
+	e := newTestEntity()
+	buf := &bytes.Buffer{}
+	p := e.newParameter()
+	p.dumpWriter = buf
+
+	e.writeDump(p, "line one")
+
+	if buf.String() != "line one\n" {
+		t.Errorf("dumpWriter = %q, want %q", buf.String(), "line one\n")
+	}
+}
+
+func TestDumpRequestSkippedWithoutDebug(t *testing.T) {
+	e := newTestEntity()
+	buf := &bytes.Buffer{}
+	p := e.newParameter()
+	p.dumpWriter = buf
+	p.debug = false
+
+	req := httptest.NewRequest(http.MethodGet, "http://example.com", nil)
+	e.dumpRequest(p, req)
+
+	if buf.Len() != 0 {
+		t.Errorf("dumpWriter = %q, want empty when debug is disabled", buf.String())
+	}
+}
+
+func TestDumpRequestAndResponseWithDebug(t *testing.T) {
+	e := newTestEntity()
+	buf := &bytes.Buffer{}
+	p := e.newParameter()
+	p.dumpWriter = buf
+	p.debug = true
+	p.requestID = "req-1"
+
+	req, _ := http.NewRequest(http.MethodGet, "http://example.com", nil)
+	e.dumpRequest(p, req)
+	if !strings.Contains(buf.String(), "[req-1] request:") {
+		t.Errorf("dumpWriter = %q, want it to contain the request dump tagged with the request ID", buf.String())
+	}
+
+	buf.Reset()
+	resp := &http.Response{
+		StatusCode: http.StatusOK,
+		Status:     "200 OK",
+		Header:     http.Header{},
+		Body:       ioutil.NopCloser(strings.NewReader("")),
+	}
+	e.dumpResponse(p, resp)
+	if !strings.Contains(buf.String(), "[req-1] response:") {
+		t.Errorf("dumpWriter = %q, want it to contain the response dump tagged with the request ID", buf.String())
+	}
+}
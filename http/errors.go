@@ -0,0 +1,114 @@
+package http
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	nethttp "net/http"
+)
+
+// maxHTTPErrorBodySize HTTPError中记录的响应体最多保留的字节数，超出部分截断
+const maxHTTPErrorBodySize = 4 << 10
+
+// HTTPError 描述一次失败的调用。StatusCode为0表示请求未收到响应（网络错误、超时等），
+// Err为具体原因，可通过errors.Unwrap/errors.Is/errors.As取到。
+type HTTPError struct {
+	Method      string
+	URL         string
+	StatusCode  int
+	Status      string
+	Header      nethttp.Header
+	Body        []byte
+	ErrorResult interface{}
+	Err         error
+}
+
+func (e *HTTPError) Error() string {
+	if e.StatusCode > 0 {
+		return fmt.Sprintf("xhttp: %s %s: %s: %s", e.Method, e.URL, e.Status, e.Err)
+	}
+	return fmt.Sprintf("xhttp: %s %s: %s", e.Method, e.URL, e.Err)
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Err
+}
+
+// newHTTPError 构建携带响应信息的HTTPError，body超出上限会被截断
+func newHTTPError(method, url string, resp *nethttp.Response, body []byte, err error) *HTTPError {
+	if len(body) > maxHTTPErrorBodySize {
+		body = body[:maxHTTPErrorBodySize]
+	}
+	e := &HTTPError{Method: method, URL: url, Body: body, Err: err}
+	if resp != nil {
+		e.StatusCode = resp.StatusCode
+		e.Status = resp.Status
+		e.Header = resp.Header
+	}
+	return e
+}
+
+// checkStatus 校验响应状态码：
+//  1. 设置了WithExpectStatus且实际状态码不在其中，返回*HTTPError；
+//  2. 状态码为4xx/5xx、设置了WithErrorResult，且未被WithExpectStatus显式放行，
+//     解析错误响应体并挂载到*HTTPError。
+func (r *entity) checkStatus(p *parameter, resp *nethttp.Response, body []byte) error {
+	mismatch := false
+	if len(p.expectStatus) > 0 {
+		mismatch = true
+		for _, code := range p.expectStatus {
+			if resp.StatusCode == code {
+				mismatch = false
+				break
+			}
+		}
+	}
+
+	isErrorStatus := resp.StatusCode >= nethttp.StatusBadRequest
+	// 仅当状态码未被WithExpectStatus显式放行时，才允许errorResult把4xx/5xx判定为错误
+	triggersError := isErrorStatus && p.errorResult != nil && (len(p.expectStatus) == 0 || mismatch)
+	if !mismatch && !triggersError {
+		return nil
+	}
+
+	httpErr := newHTTPError(string(p.method), p.url, resp, body, fmt.Errorf("unexpected status: %s", resp.Status))
+	if triggersError {
+		codec := p.responseCodec
+		if codec == nil {
+			codec, _ = defaultRegistry.get(resp.Header.Get("Content-Type"))
+		}
+		if codec != nil && nil == codec.Unmarshal(body, p.errorResult) {
+			httpErr.ErrorResult = p.errorResult
+		}
+	}
+	return httpErr
+}
+
+// IsStatus 判断err是否是状态码为code的*HTTPError
+func IsStatus(err error, code int) bool {
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return he.StatusCode == code
+	}
+	return false
+}
+
+// IsTimeout 判断err是否由超时引起
+func IsTimeout(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
+	}
+	var ne net.Error
+	return errors.As(err, &ne) && ne.Timeout()
+}
+
+// IsNetwork 判断err是否是网络层错误（请求未收到任何响应）
+func IsNetwork(err error) bool {
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return he.StatusCode == 0
+	}
+	var ne net.Error
+	return errors.As(err, &ne)
+}
@@ -0,0 +1,100 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+func jsonResponse(status int) *http.Response {
+	return &http.Response{
+		StatusCode: status,
+		Status:     http.StatusText(status),
+		Header:     http.Header{"Content-Type": []string{ContentTypeJSON}},
+	}
+}
+
+func TestCheckStatusNoOptionsNeverErrors(t *testing.T) {
+	e := newTestEntity()
+	p := e.newParameter()
+
+	if err := e.checkStatus(p, jsonResponse(http.StatusInternalServerError), nil); err != nil {
+		t.Errorf("checkStatus() err = %v, want nil when neither WithExpectStatus nor WithErrorResult is set", err)
+	}
+}
+
+func TestCheckStatusExpectStatusMismatch(t *testing.T) {
+	e := newTestEntity()
+	p := e.newParameter()
+	p.expectStatus = []int{http.StatusOK}
+
+	err := e.checkStatus(p, jsonResponse(http.StatusNotFound), nil)
+
+	var he *HTTPError
+	if !errors.As(err, &he) {
+		t.Fatalf("checkStatus() err = %v, want *HTTPError for a status outside expectStatus", err)
+	}
+	if he.ErrorResult != nil {
+		t.Errorf("ErrorResult = %v, want nil when WithErrorResult was never set", he.ErrorResult)
+	}
+}
+
+func TestCheckStatusExpectStatusMatchIsNotAnError(t *testing.T) {
+	e := newTestEntity()
+	p := e.newParameter()
+	p.expectStatus = []int{http.StatusNotFound}
+
+	if err := e.checkStatus(p, jsonResponse(http.StatusNotFound), nil); err != nil {
+		t.Errorf("checkStatus() err = %v, want nil when the status is in expectStatus", err)
+	}
+}
+
+func TestCheckStatusErrorResultWithoutExpectStatus(t *testing.T) {
+	e := newTestEntity()
+	p := e.newParameter()
+	target := map[string]interface{}{}
+	p.errorResult = &target
+
+	err := e.checkStatus(p, jsonResponse(http.StatusNotFound), []byte(`{"code":"not_found"}`))
+
+	var he *HTTPError
+	if !errors.As(err, &he) {
+		t.Fatalf("checkStatus() err = %v, want *HTTPError for a 4xx with WithErrorResult set", err)
+	}
+	if he.ErrorResult == nil {
+		t.Error("ErrorResult = nil, want the parsed error body")
+	}
+}
+
+func TestCheckStatusExpectStatusWhitelistWinsOverErrorResult(t *testing.T) {
+	// Regression test for the bug fixed in 7d4a733: WithExpectStatus explicitly
+	// whitelisting a status must not be overridden into an error just because
+	// WithErrorResult also happens to be set.
+	e := newTestEntity()
+	p := e.newParameter()
+	p.expectStatus = []int{http.StatusOK, http.StatusNotFound}
+	target := map[string]interface{}{}
+	p.errorResult = &target
+
+	if err := e.checkStatus(p, jsonResponse(http.StatusNotFound), []byte(`{"code":"not_found"}`)); err != nil {
+		t.Errorf("checkStatus() err = %v, want nil: 404 is explicitly whitelisted via WithExpectStatus", err)
+	}
+}
+
+func TestCheckStatusExpectStatusMismatchStillUsesErrorResult(t *testing.T) {
+	e := newTestEntity()
+	p := e.newParameter()
+	p.expectStatus = []int{http.StatusOK, http.StatusNotFound}
+	target := map[string]interface{}{}
+	p.errorResult = &target
+
+	err := e.checkStatus(p, jsonResponse(http.StatusInternalServerError), []byte(`{"code":"boom"}`))
+
+	var he *HTTPError
+	if !errors.As(err, &he) {
+		t.Fatalf("checkStatus() err = %v, want *HTTPError for a status outside expectStatus", err)
+	}
+	if he.ErrorResult == nil {
+		t.Error("ErrorResult = nil, want the parsed error body for a non-whitelisted status")
+	}
+}
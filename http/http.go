@@ -4,13 +4,13 @@ import (
 	"bytes"
 	"context"
 	"crypto/tls"
-	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"net/http"
 	neturl "net/url"
 	"reflect"
+	"sync"
 
 	"github.com/thoohv5/xhttp/util/transform"
 )
@@ -28,114 +28,197 @@ type IHttp interface {
 }
 
 type entity struct {
-	*parameter
+	// defaults 每次调用的初始配置模板，只读，真正的调用状态都在request()为本次调用克隆出的parameter上
+	defaults *parameter
+
+	// client 默认复用的http.Client，保证连接池/keep-alive生效
+	client *http.Client
+	// defaultTLSClientConfig New时的默认证书配置，用于判断调用方是否通过WithTLSClientConfig做了定制
+	defaultTLSClientConfig *tls.Config
+
+	// breakers 按host维度维护的熔断状态
+	breakers   map[string]*breakerState
+	breakersMu sync.Mutex
 }
 
 // New 创建
 func New() IHttp {
+	defaultTLSClientConfig := &tls.Config{
+		InsecureSkipVerify: false,
+	}
 	h := &entity{
-		parameter: &parameter{
+		defaults: &parameter{
 			method:  MethodGet,
 			timeout: DefaultTimeOut,
 			header: map[string]string{
-				"Connection":   "close",
 				"Content-Type": "application/json",
 			},
-			param: map[string]interface{}{},
-			tLSClientConfig: &tls.Config{
-				InsecureSkipVerify: false,
-			},
-			deleteUriFlag: true,
+			param:           map[string]interface{}{},
+			tLSClientConfig: defaultTLSClientConfig,
+			deleteUriFlag:   true,
 		},
+		defaultTLSClientConfig: defaultTLSClientConfig,
 	}
+	h.client = &http.Client{Transport: newDefaultTransport(defaultTLSClientConfig)}
 	return h
 }
 
-func (r *entity) withOpt(opts ...Option) error {
+// newParameter 基于entity的默认配置克隆一份本次调用私有的parameter，
+// 避免同一个IHttp实例被并发或重复调用时相互污染header/param等可变状态
+func (r *entity) newParameter() *parameter {
+	p := *r.defaults
+
+	p.header = make(map[string]string, len(r.defaults.header))
+	for key, val := range r.defaults.header {
+		p.header[key] = val
+	}
+
+	p.param = make(map[string]interface{}, len(r.defaults.param))
+	for key, val := range r.defaults.param {
+		p.param[key] = val
+	}
+
+	p.beforeRequest = nil
+	p.files = nil
+
+	return &p
+}
+
+// clientFor 根据本次调用的配置选择使用的http.Client：
+// 指定了WithClient则直接使用；指定了WithTransport/WithProxy/WithDialContext/WithTLSClientConfig
+// 等会影响传输层的选项时，临时构建一个client；否则复用entity持有的默认client以保留连接池。
+func (r *entity) clientFor(p *parameter) *http.Client {
+	if p.clientOverride != nil {
+		return p.clientOverride
+	}
+
+	if p.transport != nil {
+		return &http.Client{Transport: p.transport}
+	}
+
+	if p.proxy == nil && p.dialContext == nil && p.tLSClientConfig == r.defaultTLSClientConfig {
+		return r.client
+	}
+
+	t := newDefaultTransport(p.tLSClientConfig)
+	if p.proxy != nil {
+		t.Proxy = p.proxy
+	}
+	if p.dialContext != nil {
+		t.DialContext = p.dialContext
+	}
+	return &http.Client{Transport: t}
+}
+
+func (r *entity) withOpt(p *parameter, opts ...Option) error {
 	for _, o := range opts {
-		o.apply(r.parameter)
+		o.apply(p)
 	}
 	return nil
 }
 
 func (r *entity) request(ctx context.Context, url string, result interface{}, opts ...Option) (err error) {
+	p := r.newParameter()
+
 	opts = append([]Option{WithUrl(url)}, opts...)
 	// 可选参数
-	if err = r.withOpt(opts...); nil != err {
+	if err = r.withOpt(p, opts...); nil != err {
 		return fmt.Errorf("request withOpt err, opts: %v, %w", opts, err)
 	}
 
+	// 请求ID，用于跨日志/dump关联同一次调用
+	if p.requestID == "" {
+		p.requestID = newRequestID()
+	}
+	if _, ok := p.header["X-Request-Id"]; !ok {
+		p.header["X-Request-Id"] = p.requestID
+	}
+
 	// 预处理
-	for _, beforeRequest := range r.beforeRequest {
-		if err = beforeRequest(r.parameter); nil != err {
-			return fmt.Errorf("request callback err, r: %v, %w", r, err)
+	for _, beforeRequest := range p.beforeRequest {
+		if err = beforeRequest(p); nil != err {
+			return fmt.Errorf("request callback err, r: %v, %w", p, err)
 		}
 	}
 
-	// 组装request
-	req, err := http.NewRequestWithContext(ctx, string(r.method), r.url, r.body)
-	if nil != err {
-		return fmt.Errorf("request NewRequestWithContext err, url: %s, body: %s, %w", r.url, r.body, err)
+	// 超时通过context控制，避免覆盖共享client的Timeout
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
 	}
 
-	// 组装header
-	for key, value := range r.header {
-		req.Header.Set(key, value)
+	// SSE：按事件流持续读取，连接中断时携带Last-Event-ID自动重连
+	if p.sse != nil {
+		req, buildErr := buildRequest(ctx, p)
+		if nil != buildErr {
+			return fmt.Errorf("request build err, url: %s, %w", p.url, buildErr)
+		}
+		if err = r.runSSE(ctx, r.clientFor(p), req, p); nil != err {
+			return fmt.Errorf("request sse err, %w", err)
+		}
+		return
 	}
 
-	// 发送请求
-	client := &http.Client{Transport: &http.Transport{
-		TLSClientConfig: r.tLSClientConfig,
-	}, Timeout: r.timeout}
-	resp, err := client.Do(req)
+	// 中间件链：用户自定义中间件包裹在重试/熔断/指标/dump等内置中间件之外，最内层为实际发送请求
+	rt := RoundTrip(r.roundTrip)
+	chain := append(append([]Middleware{}, p.middlewares...), r.builtinMiddlewares()...)
+	for i := len(chain) - 1; i >= 0; i-- {
+		rt = chain[i](rt)
+	}
+
+	resp, err := rt(ctx, p)
 	if nil != err {
-		return fmt.Errorf("request do err, param: %v, %w", req, err)
+		return newHTTPError(string(p.method), p.url, resp, nil, err)
+	}
+
+	// 流式响应，由调用方自行读取并关闭body
+	if p.stream != nil {
+		if err = p.stream(resp.Body); nil != err {
+			return newHTTPError(string(p.method), p.url, resp, nil, err)
+		}
+		return
 	}
+
 	defer func() {
 		if closeErr := resp.Body.Close(); nil != closeErr {
-			errStr := ""
+			wrapped := fmt.Errorf("resp body close err, %w", closeErr)
 			if err != nil {
-				errStr = fmt.Sprintf("(%s)", err.Error())
+				wrapped = fmt.Errorf("resp body close err (%s), %w", err.Error(), closeErr)
 			}
-			err = fmt.Errorf("resp body close err, %v %w", errStr, closeErr)
+			err = newHTTPError(string(p.method), p.url, resp, nil, wrapped)
 		}
 	}()
 
 	var bodyByte []byte
-	// 完整Response
-	if r.response != nil {
-		*r.response = *resp
-		// 读取请求
-		if bodyByte, err = ioutil.ReadAll(resp.Body); nil != err {
-			return fmt.Errorf("request read err, bodyByte: %v, %w", bodyByte, err)
-		}
-		r.response.Body = ioutil.NopCloser(bytes.NewBuffer(bodyByte))
+	if bodyByte, err = ioutil.ReadAll(resp.Body); nil != err {
+		return newHTTPError(string(p.method), p.url, resp, nil, fmt.Errorf("resp body read err, %w", err))
 	}
 
-	// 不需要解析返回值
-	if result == nil {
-		if _, err = io.Copy(ioutil.Discard, resp.Body); err != nil {
-			return fmt.Errorf("resp body clear err, %w", err)
-		}
-		return
+	// 完整Response
+	if p.response != nil {
+		*p.response = *resp
+		p.response.Body = ioutil.NopCloser(bytes.NewBuffer(bodyByte))
 	}
 
-	// 读取请求
-	if len(bodyByte) == 0 {
-		if bodyByte, err = ioutil.ReadAll(resp.Body); nil != err {
-			return fmt.Errorf("request read err, bodyByte: %v, %w", bodyByte, err)
-		}
+	// 状态码校验：不在WithExpectStatus范围内，或命中4xx/5xx且设置了WithErrorResult时返回*HTTPError
+	if httpErr := r.checkStatus(p, resp, bodyByte); httpErr != nil {
+		return httpErr
 	}
 
-	// 没有内容
-	if len(bodyByte) == 0 {
+	// 不需要解析返回值，或响应没有内容
+	if result == nil || len(bodyByte) == 0 {
 		return
 	}
 
-	// 按照JSON解析返回值
-	if json.Valid(bodyByte) {
-		if err = json.Unmarshal(bodyByte, &result); nil != err {
-			return fmt.Errorf("request json un err, result: %v, %w", result, err)
+	// 按照响应codec解析返回值
+	codec := p.responseCodec
+	if codec == nil {
+		codec, _ = defaultRegistry.get(resp.Header.Get("Content-Type"))
+	}
+	if codec != nil {
+		if err = codec.Unmarshal(bodyByte, result); nil != err {
+			return newHTTPError(string(p.method), p.url, resp, bodyByte, fmt.Errorf("response codec un err, %w", err))
 		}
 		return
 	}
@@ -173,7 +256,7 @@ func (r *entity) Get(ctx context.Context, url string, result interface{}, opts .
 		netUrl.RawQuery = params.Encode()
 		r.url = netUrl.String()
 		if r.log != nil {
-			r.log.Println("Get url", r.header, r.url)
+			r.log.Println(r.requestID, "Get url", r.header, r.url)
 		}
 		return nil
 	}))
@@ -184,17 +267,21 @@ func (r *entity) Get(ctx context.Context, url string, result interface{}, opts .
 func (r *entity) Post(ctx context.Context, url string, param map[string]interface{}, result interface{}, opts ...Option) error {
 	// withMethod, withParam, WithBeforeRequest
 	opts = append(opts, WithMethod(MethodPost), WithParam(param), WithBeforeRequest(func(r *parameter) error {
-		if nil == r.param {
+		if nil == r.param && len(r.files) == 0 {
 			return nil
 		}
 		// 组装param
-		data, err := json.Marshal(r.param)
+		data, contentType, err := r.marshalBody()
 		if nil != err {
-			return fmt.Errorf("post json ma err, param: %s, %w", param, err)
+			return fmt.Errorf("post codec ma err, param: %s, %w", param, err)
 		}
 		r.SetBody(bytes.NewBuffer(data))
+		r.SetGetBody(func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(data)), nil
+		})
+		r.header["Content-Type"] = contentType
 		if r.log != nil {
-			r.log.Println("Post url", r.header, r.url, string(data))
+			r.log.Println(r.requestID, "Post url", r.header, r.url, string(data))
 		}
 		return nil
 	}))
@@ -205,17 +292,21 @@ func (r *entity) Post(ctx context.Context, url string, param map[string]interfac
 func (r *entity) Put(ctx context.Context, url string, param map[string]interface{}, result interface{}, opts ...Option) error {
 	// withMethod, withParam, WithBeforeRequest
 	opts = append(opts, WithMethod(MethodPut), WithParam(param), WithBeforeRequest(func(r *parameter) error {
-		if nil == r.param {
+		if nil == r.param && len(r.files) == 0 {
 			return nil
 		}
 		// 组装param
-		data, err := json.Marshal(r.param)
+		data, contentType, err := r.marshalBody()
 		if nil != err {
-			return fmt.Errorf("put json ma err, param: %s, %w", param, err)
+			return fmt.Errorf("put codec ma err, param: %s, %w", param, err)
 		}
 		r.SetBody(bytes.NewBuffer(data))
+		r.SetGetBody(func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(data)), nil
+		})
+		r.header["Content-Type"] = contentType
 		if r.log != nil {
-			r.log.Println("Put url", r.header, r.url, string(data))
+			r.log.Println(r.requestID, "Put url", r.header, r.url, string(data))
 		}
 		return nil
 	}))
@@ -226,17 +317,21 @@ func (r *entity) Put(ctx context.Context, url string, param map[string]interface
 func (r *entity) Delete(ctx context.Context, url string, param map[string]interface{}, result interface{}, opts ...Option) error {
 	// withMethod, WithBeforeRequest
 	opts = append(opts, WithMethod(MethodDelete), WithParam(param), WithBeforeRequest(func(r *parameter) error {
-		if nil == r.param {
+		if nil == r.param && len(r.files) == 0 {
 			return nil
 		}
 		// 组装param
-		data, err := json.Marshal(r.param)
+		data, contentType, err := r.marshalBody()
 		if nil != err {
-			return fmt.Errorf("post json ma err, param: %s, %w", param, err)
+			return fmt.Errorf("delete codec ma err, param: %s, %w", param, err)
 		}
 		r.SetBody(bytes.NewBuffer(data))
+		r.SetGetBody(func() (io.ReadCloser, error) {
+			return ioutil.NopCloser(bytes.NewReader(data)), nil
+		})
+		r.header["Content-Type"] = contentType
 		if r.log != nil {
-			r.log.Println("Delete url", r.header, r.url, string(data))
+			r.log.Println(r.requestID, "Delete url", r.header, r.url, string(data))
 		}
 		// 组装url
 		if r.deleteUriFlag {
@@ -251,7 +346,7 @@ func (r *entity) Delete(ctx context.Context, url string, param map[string]interf
 			netUrl.RawQuery = params.Encode()
 			r.url = netUrl.String()
 			if r.log != nil {
-				r.log.Println("Delete url", r.url)
+				r.log.Println(r.requestID, "Delete url", r.url)
 			}
 		}
 		return nil
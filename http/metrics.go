@@ -0,0 +1,96 @@
+package http
+
+import (
+	"context"
+	nethttp "net/http"
+	neturl "net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// metrics 一组围绕一次调用采集的Prometheus指标
+type metrics struct {
+	latency  *prometheus.HistogramVec
+	total    *prometheus.CounterVec
+	inFlight prometheus.Gauge
+}
+
+func newMetrics(registerer prometheus.Registerer) *metrics {
+	m := &metrics{
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "xhttp",
+			Name:      "request_duration_seconds",
+			Help:      "xhttp请求耗时分布",
+		}, []string{"method", "host"}),
+		total: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "xhttp",
+			Name:      "request_total",
+			Help:      "xhttp请求状态计数",
+		}, []string{"method", "host", "status"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "xhttp",
+			Name:      "in_flight_requests",
+			Help:      "xhttp当前进行中的请求数",
+		}),
+	}
+	registerer.MustRegister(m.latency, m.total, m.inFlight)
+	return m
+}
+
+var (
+	metricsMu    sync.Mutex
+	metricsCache = map[prometheus.Registerer]*metrics{}
+)
+
+// metricsFor 同一个Registerer只注册一次指标，避免重复Register时panic
+func metricsFor(registerer prometheus.Registerer) *metrics {
+	metricsMu.Lock()
+	defer metricsMu.Unlock()
+
+	if m, ok := metricsCache[registerer]; ok {
+		return m
+	}
+	m := newMetrics(registerer)
+	metricsCache[registerer] = m
+	return m
+}
+
+// hostOf 提取URL中的host，用于按host维度打标
+func hostOf(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// metricsMiddleware 采集每次调用的耗时、状态计数和进行中请求数
+func metricsMiddleware() Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, p *parameter) (*nethttp.Response, error) {
+			if p.metricsRegisterer == nil {
+				return next(ctx, p)
+			}
+
+			m := metricsFor(p.metricsRegisterer)
+			host := hostOf(p.url)
+
+			m.inFlight.Inc()
+			start := time.Now()
+			resp, err := next(ctx, p)
+			m.inFlight.Dec()
+			m.latency.WithLabelValues(string(p.method), host).Observe(time.Since(start).Seconds())
+
+			status := "error"
+			if resp != nil {
+				status = strconv.Itoa(resp.StatusCode)
+			}
+			m.total.WithLabelValues(string(p.method), host, status).Inc()
+
+			return resp, err
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	nethttp "net/http"
+	"time"
+)
+
+// RoundTrip 执行一次调用并返回响应，p携带了本次调用的全部上下文（URL、方法、header、body等）
+type RoundTrip func(ctx context.Context, p *parameter) (*nethttp.Response, error)
+
+// Middleware 包装RoundTrip，可在请求前后插入逻辑（dump、指标、熔断、重试等）
+type Middleware func(next RoundTrip) RoundTrip
+
+// buildRequest 根据parameter组装一个*http.Request，body优先通过getBody获取以支持重复读取
+func buildRequest(ctx context.Context, p *parameter) (*nethttp.Request, error) {
+	body := p.body
+	if p.getBody != nil {
+		rc, err := p.getBody()
+		if err != nil {
+			return nil, err
+		}
+		body = rc
+	}
+
+	req, err := nethttp.NewRequestWithContext(ctx, string(p.method), p.url, body)
+	if err != nil {
+		return nil, err
+	}
+
+	for key, value := range p.header {
+		req.Header.Set(key, value)
+	}
+	if p.getBody != nil {
+		req.GetBody = p.getBody
+	}
+
+	return req, nil
+}
+
+// roundTrip 是实际发起调用的终端RoundTrip，位于中间件链的最内层
+func (r *entity) roundTrip(ctx context.Context, p *parameter) (*nethttp.Response, error) {
+	req, err := buildRequest(ctx, p)
+	if err != nil {
+		return nil, err
+	}
+	return r.clientFor(p).Do(req)
+}
+
+// builtinMiddlewares 内置中间件，由外到内依次为：重试、熔断、指标、dump
+func (r *entity) builtinMiddlewares() []Middleware {
+	return []Middleware{
+		retryMiddleware(),
+		r.circuitBreakerMiddleware(),
+		metricsMiddleware(),
+		dumpMiddleware(r),
+	}
+}
+
+// dumpMiddleware 在请求发出前/响应返回后记录完整报文，流式/SSE响应不做响应侧dump
+func dumpMiddleware(r *entity) Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, p *parameter) (*nethttp.Response, error) {
+			if p.debug {
+				if req, err := buildRequest(ctx, p); err == nil {
+					r.dumpRequest(p, req)
+				}
+			}
+
+			resp, err := next(ctx, p)
+			if p.debug && err == nil && p.stream == nil {
+				r.dumpResponse(p, resp)
+			}
+			return resp, err
+		}
+	}
+}
+
+// retryMiddleware 按RetryPolicy重试，每次重试都会重新走一遍内层中间件（熔断、指标、dump均按次统计）
+func retryMiddleware() Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, p *parameter) (*nethttp.Response, error) {
+			policy := p.retryPolicy
+			if policy == nil {
+				return next(ctx, p)
+			}
+
+			maxAttempts := policy.MaxAttempts
+			if maxAttempts <= 0 {
+				maxAttempts = 1
+			}
+
+			var deadline time.Time
+			if policy.Budget > 0 {
+				deadline = time.Now().Add(policy.Budget)
+			}
+
+			var resp *nethttp.Response
+			var err error
+			for attempt := 1; attempt <= maxAttempts; attempt++ {
+				resp, err = next(ctx, p)
+				if attempt == maxAttempts || !policy.retryOn(resp, err) {
+					return resp, err
+				}
+
+				d := policy.delay(attempt, resp)
+				if !deadline.IsZero() && time.Now().Add(d).After(deadline) {
+					return resp, err
+				}
+				if policy.OnRetry != nil {
+					policy.OnRetry(attempt, resp, err, d)
+				}
+				if resp != nil {
+					_ = resp.Body.Close()
+				}
+
+				timer := time.NewTimer(d)
+				select {
+				case <-ctx.Done():
+					timer.Stop()
+					return resp, ctx.Err()
+				case <-timer.C:
+				}
+			}
+
+			return resp, err
+		}
+	}
+}
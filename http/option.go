@@ -1,10 +1,15 @@
 package http
 
 import (
+	"context"
 	"crypto/tls"
 	"io"
+	"net"
 	nethttp "net/http"
+	neturl "net/url"
 	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
 )
 
 // parameter 参数
@@ -23,12 +28,50 @@ type parameter struct {
 	beforeRequest []func(r *parameter) error
 	// reader
 	body io.Reader
+	// getBody 重新获取body，用于重试/重定向时回放请求体
+	getBody func() (io.ReadCloser, error)
 	// tls
 	tLSClientConfig *tls.Config
 	// log
 	log ILogger
 	// deleteUriFlag
 	deleteUriFlag bool
+	// retryPolicy 重试策略
+	retryPolicy *RetryPolicy
+	// clientOverride 自定义http.Client，设置后优先于transport/proxy/dialContext
+	clientOverride *nethttp.Client
+	// transport 自定义RoundTripper
+	transport nethttp.RoundTripper
+	// proxy 代理函数
+	proxy func(*nethttp.Request) (*neturl.URL, error)
+	// dialContext 自定义拨号函数
+	dialContext func(ctx context.Context, network, addr string) (net.Conn, error)
+	// debug 是否记录请求/响应的完整报文
+	debug bool
+	// dumpWriter debug日志输出位置，未设置ILogger时使用
+	dumpWriter io.Writer
+	// requestID 本次调用的请求ID，用于跨日志/dump关联，未指定时自动生成
+	requestID string
+	// requestCodec 请求体编解码器，未指定时文件优先走multipart，否则为JSON
+	requestCodec Codec
+	// responseCodec 响应体编解码器，未指定时按响应Content-Type自动选择
+	responseCodec Codec
+	// files 待上传的multipart文件
+	files []file
+	// stream 流式响应处理函数，设置后跳过整体读取，由调用方负责读取并关闭body
+	stream func(io.ReadCloser) error
+	// sse SSE事件处理函数，设置后按事件流解析并自动重连
+	sse func(Event) error
+	// middlewares 用户自定义中间件，包裹在内置中间件之外
+	middlewares []Middleware
+	// metricsRegisterer 设置后开启Prometheus指标采集
+	metricsRegisterer prometheus.Registerer
+	// circuitBreaker 设置后按host维度开启熔断
+	circuitBreaker *CircuitBreakerConfig
+	// expectStatus 期望的状态码，非空时实际状态码不在其中将返回*HTTPError
+	expectStatus []int
+	// errorResult 4xx/5xx时用于解析错误响应体的目标对象
+	errorResult interface{}
 
 	// 返回值
 	response *nethttp.Response
@@ -38,6 +81,11 @@ func (p *parameter) SetBody(body io.Reader) {
 	p.body = body
 }
 
+// SetGetBody 设置body重新获取方法
+func (p *parameter) SetGetBody(getBody func() (io.ReadCloser, error)) {
+	p.getBody = getBody
+}
+
 type Option interface {
 	apply(*parameter)
 }
@@ -121,3 +169,141 @@ func WithDeleteURIFlag(flag bool) Option {
 		r.deleteUriFlag = flag
 	})
 }
+
+// WithRetry 重试策略
+func WithRetry(policy *RetryPolicy) Option {
+	return optionFunc(func(r *parameter) {
+		r.retryPolicy = policy
+	})
+}
+
+// WithClient 自定义http.Client，设置后将忽略WithTransport/WithProxy/WithDialContext
+func WithClient(client *nethttp.Client) Option {
+	return optionFunc(func(r *parameter) {
+		r.clientOverride = client
+	})
+}
+
+// WithTransport 自定义RoundTripper
+func WithTransport(transport nethttp.RoundTripper) Option {
+	return optionFunc(func(r *parameter) {
+		r.transport = transport
+	})
+}
+
+// WithProxy 代理函数
+func WithProxy(proxy func(*nethttp.Request) (*neturl.URL, error)) Option {
+	return optionFunc(func(r *parameter) {
+		r.proxy = proxy
+	})
+}
+
+// WithDialContext 自定义拨号函数
+func WithDialContext(dialContext func(ctx context.Context, network, addr string) (net.Conn, error)) Option {
+	return optionFunc(func(r *parameter) {
+		r.dialContext = dialContext
+	})
+}
+
+// WithKeepAlive 是否启用长连接，默认开启；传入false可显式关闭（header中带Connection: close）
+func WithKeepAlive(keepAlive bool) Option {
+	return optionFunc(func(r *parameter) {
+		if keepAlive {
+			delete(r.header, "Connection")
+			return
+		}
+		r.header["Connection"] = "close"
+	})
+}
+
+// WithDebug 是否记录请求/响应的完整报文
+func WithDebug(debug bool) Option {
+	return optionFunc(func(r *parameter) {
+		r.debug = debug
+	})
+}
+
+// WithDumpWriter debug日志输出位置，未设置WithLogger时生效
+func WithDumpWriter(writer io.Writer) Option {
+	return optionFunc(func(r *parameter) {
+		r.dumpWriter = writer
+	})
+}
+
+// WithRequestID 指定本次调用的请求ID，用于串联跨服务调用链路，未指定时自动生成
+func WithRequestID(requestID string) Option {
+	return optionFunc(func(r *parameter) {
+		r.requestID = requestID
+	})
+}
+
+// WithRequestCodec 请求体编解码器，未指定时文件优先走multipart，否则为JSON
+func WithRequestCodec(codec Codec) Option {
+	return optionFunc(func(r *parameter) {
+		r.requestCodec = codec
+	})
+}
+
+// WithResponseCodec 响应体编解码器，未指定时按响应Content-Type自动选择
+func WithResponseCodec(codec Codec) Option {
+	return optionFunc(func(r *parameter) {
+		r.responseCodec = codec
+	})
+}
+
+// WithFile 追加一个multipart文件字段，配合Post/Put使用以上传文件
+func WithFile(field, filename string, reader io.Reader) Option {
+	return optionFunc(func(r *parameter) {
+		r.files = append(r.files, file{field: field, filename: filename, reader: reader})
+	})
+}
+
+// WithStream 流式响应处理，设置后不再整体读取body，由handler自行读取并关闭
+func WithStream(handler func(io.ReadCloser) error) Option {
+	return optionFunc(func(r *parameter) {
+		r.stream = handler
+	})
+}
+
+// WithSSE 以SSE(Server-Sent Events)方式消费响应，连接中断时携带Last-Event-ID自动重连
+func WithSSE(handler func(Event) error) Option {
+	return optionFunc(func(r *parameter) {
+		r.sse = handler
+	})
+}
+
+// WithMiddleware 追加自定义中间件，包裹在重试/熔断/指标/dump等内置中间件之外
+func WithMiddleware(middlewares ...Middleware) Option {
+	return optionFunc(func(r *parameter) {
+		r.middlewares = append(r.middlewares, middlewares...)
+	})
+}
+
+// WithMetrics 开启Prometheus指标采集（请求耗时、状态计数、进行中请求数）
+func WithMetrics(registerer prometheus.Registerer) Option {
+	return optionFunc(func(r *parameter) {
+		r.metricsRegisterer = registerer
+	})
+}
+
+// WithCircuitBreaker 按host维度开启熔断
+func WithCircuitBreaker(config *CircuitBreakerConfig) Option {
+	return optionFunc(func(r *parameter) {
+		r.circuitBreaker = config
+	})
+}
+
+// WithExpectStatus 期望的状态码，实际状态码不在其中时返回*HTTPError而非继续解析返回值
+func WithExpectStatus(codes ...int) Option {
+	return optionFunc(func(r *parameter) {
+		r.expectStatus = codes
+	})
+}
+
+// WithErrorResult 4xx/5xx响应体按响应codec解析到v，并挂载到返回的*HTTPError.ErrorResult；
+// 被WithExpectStatus显式放行的状态码不会被当作错误，也不做此解析
+func WithErrorResult(v interface{}) Option {
+	return optionFunc(func(r *parameter) {
+		r.errorResult = v
+	})
+}
@@ -0,0 +1,103 @@
+package http
+
+import (
+	"math"
+	"math/rand"
+	nethttp "net/http"
+	"strconv"
+	"time"
+)
+
+// RetryPolicy 重试策略
+type RetryPolicy struct {
+	// MaxAttempts 最大尝试次数（含首次请求），小于等于1表示不重试
+	MaxAttempts int
+	// BaseDelay 基础延迟
+	BaseDelay time.Duration
+	// MaxDelay 指数退避延迟的上限；抖动在截断之后施加，最终延迟最多可达MaxDelay*(1+Jitter)，Retry-After给出的延迟不受其限制
+	MaxDelay time.Duration
+	// Jitter 抖动比例，取值范围[0,1]，最终延迟在[delay*(1-Jitter), delay*(1+Jitter)]之间
+	Jitter float64
+	// Budget 本次请求（含重试）的时间预算，小于等于0表示不限制；
+	// 仅在每次重试等待前检查，等待结束时会超出预算则不再重试，单次请求本身的耗时不受其限制
+	Budget time.Duration
+	// RetryOn 判断本次响应/错误是否需要重试，为空时使用DefaultRetryOn
+	RetryOn func(resp *nethttp.Response, err error) bool
+	// OnRetry 每次重试前触发的钩子，attempt表示即将进行的第几次重试（从1开始）
+	OnRetry func(attempt int, resp *nethttp.Response, err error, delay time.Duration)
+}
+
+// DefaultRetryPolicy 默认重试策略：最多尝试3次（含首次请求，即最多重试2次），基础延迟100ms，最大延迟2s，抖动20%
+func DefaultRetryPolicy() *RetryPolicy {
+	return &RetryPolicy{
+		MaxAttempts: 3,
+		BaseDelay:   100 * time.Millisecond,
+		MaxDelay:    2 * time.Second,
+		Jitter:      0.2,
+	}
+}
+
+// DefaultRetryOn 默认重试判断：网络错误、408、429（尊重Retry-After）、5xx（除501）
+func DefaultRetryOn(resp *nethttp.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	if resp == nil {
+		return false
+	}
+	switch resp.StatusCode {
+	case nethttp.StatusRequestTimeout, nethttp.StatusTooManyRequests:
+		return true
+	case nethttp.StatusNotImplemented:
+		return false
+	}
+	return resp.StatusCode >= 500
+}
+
+func (p *RetryPolicy) retryOn(resp *nethttp.Response, err error) bool {
+	if p.RetryOn != nil {
+		return p.RetryOn(resp, err)
+	}
+	return DefaultRetryOn(resp, err)
+}
+
+// delay 计算第attempt次重试（从1开始）的延迟时间，优先尊重Retry-After
+func (p *RetryPolicy) delay(attempt int, resp *nethttp.Response) time.Duration {
+	if resp != nil {
+		if d, ok := retryAfterDelay(resp); ok {
+			return d
+		}
+	}
+
+	d := float64(p.BaseDelay) * math.Pow(2, float64(attempt-1))
+	if p.MaxDelay > 0 && d > float64(p.MaxDelay) {
+		d = float64(p.MaxDelay)
+	}
+	if p.Jitter > 0 {
+		delta := d * p.Jitter
+		d = d - delta + rand.Float64()*2*delta
+	}
+	if d < 0 {
+		d = 0
+	}
+
+	return time.Duration(d)
+}
+
+// retryAfterDelay 解析Retry-After响应头，支持秒数和HTTP-date两种格式
+func retryAfterDelay(resp *nethttp.Response) (time.Duration, bool) {
+	v := resp.Header.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Duration(secs) * time.Second, true
+	}
+	if t, err := nethttp.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
@@ -0,0 +1,90 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDefaultRetryOn(t *testing.T) {
+	cases := []struct {
+		name string
+		resp *http.Response
+		err  error
+		want bool
+	}{
+		{"network error", nil, errors.New("dial tcp: timeout"), true},
+		{"nil response no error", nil, nil, false},
+		{"408 request timeout", &http.Response{StatusCode: http.StatusRequestTimeout}, nil, true},
+		{"429 too many requests", &http.Response{StatusCode: http.StatusTooManyRequests}, nil, true},
+		{"501 not implemented", &http.Response{StatusCode: http.StatusNotImplemented}, nil, false},
+		{"503 service unavailable", &http.Response{StatusCode: http.StatusServiceUnavailable}, nil, true},
+		{"200 ok", &http.Response{StatusCode: http.StatusOK}, nil, false},
+		{"404 not found", &http.Response{StatusCode: http.StatusNotFound}, nil, false},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			if got := DefaultRetryOn(c.resp, c.err); got != c.want {
+				t.Errorf("DefaultRetryOn() = %v, want %v", got, c.want)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyDelay(t *testing.T) {
+	p := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	if d := p.delay(1, nil); d != 100*time.Millisecond {
+		t.Errorf("attempt 1 delay = %v, want %v", d, 100*time.Millisecond)
+	}
+	if d := p.delay(2, nil); d != 200*time.Millisecond {
+		t.Errorf("attempt 2 delay = %v, want %v", d, 200*time.Millisecond)
+	}
+	if d := p.delay(10, nil); d != time.Second {
+		t.Errorf("attempt 10 delay = %v, want capped at %v", d, time.Second)
+	}
+}
+
+func TestRetryPolicyDelayJitter(t *testing.T) {
+	p := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second, Jitter: 0.2}
+
+	for i := 0; i < 50; i++ {
+		d := p.delay(1, nil)
+		if d < 80*time.Millisecond || d > 120*time.Millisecond {
+			t.Fatalf("delay %v outside jitter range [80ms, 120ms]", d)
+		}
+	}
+}
+
+func TestRetryPolicyDelayRetryAfterWins(t *testing.T) {
+	p := &RetryPolicy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"5"}}}
+
+	if d := p.delay(1, resp); d != 5*time.Second {
+		t.Errorf("delay with Retry-After = %v, want %v", d, 5*time.Second)
+	}
+}
+
+func TestRetryAfterDelayInvalid(t *testing.T) {
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"not-a-number-or-date"}}}
+	if _, ok := retryAfterDelay(resp); ok {
+		t.Error("retryAfterDelay() ok = true, want false for invalid header")
+	}
+}
+
+func TestRetryPolicyRetryOnCustom(t *testing.T) {
+	called := false
+	p := &RetryPolicy{RetryOn: func(resp *http.Response, err error) bool {
+		called = true
+		return false
+	}}
+
+	if p.retryOn(&http.Response{StatusCode: http.StatusInternalServerError}, nil) {
+		t.Error("retryOn() = true, want false from custom RetryOn")
+	}
+	if !called {
+		t.Error("custom RetryOn was not invoked")
+	}
+}
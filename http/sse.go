@@ -0,0 +1,157 @@
+package http
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	nethttp "net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Event 表示一条SSE(Server-Sent Events)事件
+type Event struct {
+	// ID 事件ID，服务端/客户端通过Last-Event-ID用它续传
+	ID string
+	// Event 事件类型，未指定时为"message"
+	Event string
+	// Data 事件数据，多行data以\n拼接
+	Data string
+	// Retry 服务端通过retry字段建议的重连间隔
+	Retry time.Duration
+}
+
+// scanSSE 按W3C事件流语法解析body，每解析出一个事件即回调handler；
+// lastEventID/retryDelay用于在连接中断后让调用方续传
+func scanSSE(body io.Reader, handler func(Event) error, lastEventID *string, retryDelay *time.Duration) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1<<20)
+
+	var (
+		id    string
+		event string
+		data  strings.Builder
+		has   bool
+		retry time.Duration
+	)
+
+	dispatch := func() error {
+		if !has {
+			return nil
+		}
+		e := Event{ID: id, Event: event, Data: strings.TrimSuffix(data.String(), "\n"), Retry: retry}
+		if e.Event == "" {
+			e.Event = "message"
+		}
+		if id != "" {
+			*lastEventID = id
+		}
+		data.Reset()
+		has, event, retry = false, "", 0
+		return handler(e)
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			if err := dispatch(); err != nil {
+				return err
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := splitSSEField(line)
+		switch field {
+		case "id":
+			id = value
+		case "event":
+			event = value
+		case "data":
+			data.WriteString(value)
+			data.WriteByte('\n')
+			has = true
+		case "retry":
+			if ms, err := strconv.Atoi(value); err == nil {
+				retry = time.Duration(ms) * time.Millisecond
+				*retryDelay = retry
+			}
+		}
+	}
+
+	if err := dispatch(); err != nil {
+		return err
+	}
+
+	return scanner.Err()
+}
+
+// splitSSEField 按冒号拆分字段名和值，值前的单个空格会被去掉
+func splitSSEField(line string) (string, string) {
+	i := strings.IndexByte(line, ':')
+	if i < 0 {
+		return line, ""
+	}
+	field, value := line[:i], line[i+1:]
+	if strings.HasPrefix(value, " ") {
+		value = value[1:]
+	}
+	return field, value
+}
+
+// runSSE 持续读取SSE事件流，连接中断时携带Last-Event-ID自动重连
+func (r *entity) runSSE(ctx context.Context, client *nethttp.Client, req *nethttp.Request, p *parameter) error {
+	policy := p.retryPolicy
+	if policy == nil {
+		policy = DefaultRetryPolicy()
+	}
+	maxAttempts := policy.MaxAttempts
+	if maxAttempts <= 0 {
+		maxAttempts = 1
+	}
+
+	lastEventID := ""
+	retryDelay := policy.BaseDelay
+
+	for attempt := 1; ; attempt++ {
+		if lastEventID != "" {
+			req.Header.Set("Last-Event-ID", lastEventID)
+		}
+		if attempt > 1 && req.GetBody != nil {
+			if body, bodyErr := req.GetBody(); bodyErr == nil {
+				req.Body = body
+			}
+		}
+
+		resp, err := client.Do(req)
+		if err == nil && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+			_ = resp.Body.Close()
+			err = fmt.Errorf("sse unexpected status: %s", resp.Status)
+		}
+
+		if err == nil {
+			err = scanSSE(resp.Body, p.sse, &lastEventID, &retryDelay)
+			_ = resp.Body.Close()
+			if err == nil {
+				// 服务端正常关闭连接
+				return nil
+			}
+		}
+
+		if attempt >= maxAttempts {
+			return fmt.Errorf("sse reconnect err, %w", err)
+		}
+
+		timer := time.NewTimer(retryDelay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
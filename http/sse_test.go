@@ -0,0 +1,144 @@
+package http
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestScanSSEBasicEvents(t *testing.T) {
+	body := "id: 1\nevent: greeting\ndata: hello\n\ndata: world\n\n"
+
+	var events []Event
+	lastEventID := ""
+	var retryDelay time.Duration
+	if err := scanSSE(strings.NewReader(body), func(e Event) error {
+		events = append(events, e)
+		return nil
+	}, &lastEventID, &retryDelay); err != nil {
+		t.Fatalf("scanSSE() err = %v", err)
+	}
+
+	if len(events) != 2 {
+		t.Fatalf("len(events) = %d, want 2", len(events))
+	}
+	if events[0].ID != "1" || events[0].Event != "greeting" || events[0].Data != "hello" {
+		t.Errorf("events[0] = %+v, want ID=1 Event=greeting Data=hello", events[0])
+	}
+	if events[1].Event != "message" || events[1].Data != "world" {
+		t.Errorf("events[1] = %+v, want default Event=message Data=world", events[1])
+	}
+	if lastEventID != "1" {
+		t.Errorf("lastEventID = %q, want %q (unset id on later events does not clear it)", lastEventID, "1")
+	}
+}
+
+func TestScanSSEMultilineData(t *testing.T) {
+	body := "data: line1\ndata: line2\n\n"
+
+	var got Event
+	lastEventID := ""
+	var retryDelay time.Duration
+	if err := scanSSE(strings.NewReader(body), func(e Event) error {
+		got = e
+		return nil
+	}, &lastEventID, &retryDelay); err != nil {
+		t.Fatalf("scanSSE() err = %v", err)
+	}
+
+	if got.Data != "line1\nline2" {
+		t.Errorf("Data = %q, want %q", got.Data, "line1\nline2")
+	}
+}
+
+func TestScanSSECommentsIgnored(t *testing.T) {
+	body := ": this is a comment\ndata: hello\n\n"
+
+	var got Event
+	lastEventID := ""
+	var retryDelay time.Duration
+	if err := scanSSE(strings.NewReader(body), func(e Event) error {
+		got = e
+		return nil
+	}, &lastEventID, &retryDelay); err != nil {
+		t.Fatalf("scanSSE() err = %v", err)
+	}
+
+	if got.Data != "hello" {
+		t.Errorf("Data = %q, want %q (comment line should be skipped)", got.Data, "hello")
+	}
+}
+
+func TestScanSSERetryField(t *testing.T) {
+	body := "retry: 5000\ndata: hi\n\n"
+
+	lastEventID := ""
+	retryDelay := time.Second
+	if err := scanSSE(strings.NewReader(body), func(e Event) error {
+		return nil
+	}, &lastEventID, &retryDelay); err != nil {
+		t.Fatalf("scanSSE() err = %v", err)
+	}
+
+	if retryDelay != 5*time.Second {
+		t.Errorf("retryDelay = %v, want %v", retryDelay, 5*time.Second)
+	}
+}
+
+func TestScanSSETrailingEventWithoutBlankLine(t *testing.T) {
+	body := "data: last\n"
+
+	var events []Event
+	lastEventID := ""
+	var retryDelay time.Duration
+	if err := scanSSE(strings.NewReader(body), func(e Event) error {
+		events = append(events, e)
+		return nil
+	}, &lastEventID, &retryDelay); err != nil {
+		t.Fatalf("scanSSE() err = %v", err)
+	}
+
+	if len(events) != 1 || events[0].Data != "last" {
+		t.Errorf("events = %+v, want a single dispatch for the trailing event with no closing blank line", events)
+	}
+}
+
+func TestScanSSEHandlerErrorStopsScan(t *testing.T) {
+	body := "data: one\n\ndata: two\n\n"
+	wantErr := errors.New("handler boom")
+
+	calls := 0
+	lastEventID := ""
+	var retryDelay time.Duration
+	err := scanSSE(strings.NewReader(body), func(e Event) error {
+		calls++
+		return wantErr
+	}, &lastEventID, &retryDelay)
+
+	if !errors.Is(err, wantErr) {
+		t.Fatalf("scanSSE() err = %v, want %v", err, wantErr)
+	}
+	if calls != 1 {
+		t.Errorf("handler called %d times, want 1 (scan should stop on first error)", calls)
+	}
+}
+
+func TestSplitSSEField(t *testing.T) {
+	cases := []struct {
+		line      string
+		wantField string
+		wantValue string
+	}{
+		{"data: hello", "data", "hello"},
+		{"data:hello", "data", "hello"},
+		{"data:  hello", "data", " hello"},
+		{"event", "event", ""},
+	}
+	for _, c := range cases {
+		field, value := splitSSEField(c.line)
+		if field != c.wantField || value != c.wantValue {
+			t.Errorf("splitSSEField(%q) = (%q, %q), want (%q, %q)", c.line, field, value, c.wantField, c.wantValue)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package http
+
+import (
+	"crypto/tls"
+	nethttp "net/http"
+	"time"
+)
+
+// newDefaultTransport 默认传输层配置：开启连接池复用与HTTP/2
+func newDefaultTransport(tLSClientConfig *tls.Config) *nethttp.Transport {
+	return &nethttp.Transport{
+		TLSClientConfig:       tLSClientConfig,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		ForceAttemptHTTP2:     true,
+	}
+}